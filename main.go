@@ -23,23 +23,39 @@ package main
  */
 
 import (
+	"bufio"
+	"errors"
 	"flag"
 	"fmt"
+	"hcache/pkg/exporter"
+	"hcache/pkg/fastwalk"
+	"hcache/pkg/snapshot"
 	"hcache/pkg/utils"
+	"hcache/pkg/vfs"
+	"io/fs"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	pcstat "github.com/tobert/pcstat/pkg"
 )
 
 var (
-	pidFlag, topFlag                            int
+	pidFlag, topFlag, jobsFlag                  int
 	terseFlag, nohdrFlag, jsonFlag, unicodeFlag bool
 	plainFlag, ppsFlag, histoFlag, bnameFlag    bool
+	cgroupFlag, containerFlag, serveFlag        string
+	archiveFlag, sshFlag, sshHelperFlag         string
+	snapshotFlag, diffFlag                      string
+	intervalFlag, watchFlag                     time.Duration
+	pagemapFlag                                 bool
 )
 
 func init() {
@@ -54,6 +70,29 @@ func init() {
 	flag.BoolVar(&ppsFlag, "pps", false, "include the per-page status in JSON output")
 	flag.BoolVar(&histoFlag, "histo", false, "print a simple histogram instead of raw data")
 	flag.BoolVar(&bnameFlag, "bname", false, "convert paths to basename to narrow the output")
+	flag.StringVar(&cgroupFlag, "cgroup", "", "show all open maps for every pid in the given cgroup path")
+	flag.StringVar(&containerFlag, "container", "", "show all open maps for every pid in the given container ID (docker/podman/containerd)")
+	flag.StringVar(&serveFlag, "serve", "", "run as a daemon, periodically rescanning the configured targets and serving Prometheus/JSON metrics on the given address, e.g. :9099")
+	flag.DurationVar(&intervalFlag, "interval", 15*time.Second, "rescan interval for -serve")
+	flag.IntVar(&jobsFlag, "j", runtime.NumCPU(), "number of parallel workers used to scan /proc and fetch pcstat data for -top")
+	flag.StringVar(&archiveFlag, "archive", "", "analyze a /proc snapshot captured in this tar/zip archive instead of the live host")
+	flag.StringVar(&sshFlag, "ssh", "", "analyze a remote host over SSH instead of the live host, e.g. user@host[:port]")
+	flag.StringVar(&sshHelperFlag, "ssh-helper", "hcache", "path to an hcache binary on the -ssh remote, used to read back page-cache bitmaps")
+	flag.StringVar(&snapshotFlag, "snapshot", "", "write a snapshot of the current targets' cache status to this file and exit")
+	flag.StringVar(&diffFlag, "diff", "", "diff an old snapshot written by -snapshot against a new one (given as a bare file argument) or a fresh live scan")
+	flag.DurationVar(&watchFlag, "watch", 0, "keep a rolling in-memory snapshot and print the top -top files by |Δcached pages| every interval")
+	flag.BoolVar(&pagemapFlag, "pagemap", false, "print the given file's page-cache bitmap ('0'/'1' per page) on the live host; this is what -ssh-helper execs remotely for -ssh's Mincore")
+}
+
+// switchMountNs enters pid's mount namespace so overlayfs-mounted paths
+// inside a container resolve to their real backing files on the host. This
+// is only meaningful for the live-host backend: an archived snapshot has no
+// mount namespace to switch into, and the -ssh backend's remote commands
+// already run in whatever namespace the SSH daemon started in.
+func switchMountNs(fsys vfs.FileSystem, pid int) {
+	if _, ok := fsys.(vfs.Local); ok {
+		pcstat.SwitchMountNs(pid)
+	}
 }
 
 func uniqueSlice(slice *[]string) {
@@ -70,25 +109,90 @@ func uniqueSlice(slice *[]string) {
 	*slice = (*slice)[:total]
 }
 
-func getStatsFromFiles(files []string) PcStatusList {
+// statusFromVFS computes a PcStatus for name by combining fsys.Stat and
+// fsys.Mincore, so it works uniformly whether fsys is the live host (where
+// Mincore is backed by the real mincore(2) syscall), an archived snapshot,
+// or a remote host over SSH.
+func statusFromVFS(fsys vfs.FileSystem, name string) (pcstat.PcStatus, error) {
+	info, err := fsys.Stat(name)
+	if err != nil {
+		return pcstat.PcStatus{}, err
+	}
+
+	pages, err := fsys.Mincore(name)
+	if err != nil {
+		return pcstat.PcStatus{}, err
+	}
+
+	cached := 0
+	for _, resident := range pages {
+		if resident {
+			cached++
+		}
+	}
+
+	var percent float64
+	if len(pages) > 0 {
+		percent = 100 * float64(cached) / float64(len(pages))
+	}
+
+	displayName := name
+	// convert long paths to their basename with the -bname flag
+	// only the reported name changes; name itself, already used for the
+	// Stat/Mincore calls above, is left as it is
+	if bnameFlag {
+		displayName = path.Base(name)
+	}
+
+	return pcstat.PcStatus{
+		Name:     displayName,
+		Size:     info.Size,
+		Pages:    len(pages),
+		Uncached: len(pages) - cached,
+		Cached:   cached,
+		Percent:  percent,
+		PPStat:   pages,
+	}, nil
+}
+
+func getStatsFromFiles(fsys vfs.FileSystem, files []string) PcStatusList {
 
 	stats := make(PcStatusList, 0, len(files))
 	for _, fname := range files {
-		status, err := pcstat.GetPcStatus(fname)
+		status, err := statusFromVFS(fsys, fname)
 		if err != nil {
 			log.Printf("skipping %q: %v", fname, err)
 			continue
 		}
 
-		// convert long paths to their basename with the -bname flag
-		// this overwrites the original filename in pcs but it doesn't matter since
-		// it's not used to access the file again -- and should not be!
-		if bnameFlag {
-			status.Name = path.Base(fname)
+		stats = append(stats, status)
+	}
+	return stats
+}
+
+// getStatsFromFilesParallel is the -top counterpart to getStatsFromFiles: it
+// fans the fsys.Stat/Mincore calls out across a -j sized worker pool since
+// -top's results get sorted and truncated afterwards anyway, so the
+// input-order guarantee getStatsFromFiles gives explicit file arguments
+// isn't needed here.
+func getStatsFromFilesParallel(fsys vfs.FileSystem, files []string, workers int) PcStatusList {
+	var mu sync.Mutex
+	stats := make(PcStatusList, 0, len(files))
+
+	fastwalk.Walk(files, workers, func(fname string) error {
+		status, err := statusFromVFS(fsys, fname)
+		if err != nil {
+			log.Printf("skipping %q: %v", fname, err)
+			return nil
 		}
 
+		mu.Lock()
 		stats = append(stats, status)
-	}
+		mu.Unlock()
+
+		return nil
+	})
+
 	return stats
 }
 
@@ -108,35 +212,77 @@ func formatStats(stats PcStatusList) {
 	}
 }
 
-func top(top int) {
+// topFiles walks every running process with a non-zero RSS and unions their
+// open file maps. PID scanning is fanned out across a -j sized worker pool
+// (mirroring fastwalk's approach to parallel directory traversal) and
+// discovered paths are deduped through a single sync.Map, since the old
+// uniqueSlice pass over a serially-built slice was the dominant cost on
+// hosts with thousands of processes and tens of thousands of fds.
+// The process list comes from utils.Processes on the live host whatever
+// backend fsys is; only each pid's fd directory is read through fsys.
+func topFiles(fsys vfs.FileSystem) ([]string, error) {
 	p, err := utils.Processes()
 	if err != nil {
-		log.Fatalf("err: %s", err)
+		return nil, err
 	}
 
 	if len(p) <= 0 {
-		log.Fatal("Cannot find any process.")
+		return nil, fmt.Errorf("cannot find any process")
 	}
 
-	results := make([]utils.Process, 0, 50)
-
+	pids := make([]int, 0, len(p))
 	for _, p1 := range p {
 		if p1.RSS() != 0 {
-			results = append(results, p1)
+			pids = append(pids, p1.Pid())
 		}
 	}
 
-	var files []string
-
-	for _, process := range results {
-		pcstat.SwitchMountNs(process.Pid())
-		maps := getPidLds(process.Pid())
-		files = append(files, maps...)
+	jobs := make([]string, len(pids))
+	for i, pid := range pids {
+		// fastwalk.Walk just needs a unique, stable key per job; the pid
+		// itself is parsed back out of that key below
+		jobs[i] = strconv.Itoa(pid)
 	}
 
-	uniqueSlice(&files)
+	var seen sync.Map
 
-	stats := getStatsFromFiles(files)
+	fastwalk.Walk(jobs, jobsFlag, func(job string) error {
+		pid, err := strconv.Atoi(job)
+		if err != nil {
+			return err
+		}
+
+		// switchMountNs's setns(2) call and the ReadDir/Readlink calls inside
+		// getPidLds that depend on it all have to land on the same OS thread,
+		// or the Go scheduler can migrate this goroutine (or hand the thread
+		// to a sibling worker) between them and resolve this pid's paths
+		// against the wrong container's mount namespace.
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		switchMountNs(fsys, pid)
+		for _, f := range getPidLds(fsys, pid) {
+			seen.Store(f, struct{}{})
+		}
+		return nil
+	})
+
+	files := make([]string, 0)
+	seen.Range(func(key, _ interface{}) bool {
+		files = append(files, key.(string))
+		return true
+	})
+
+	return files, nil
+}
+
+func top(fsys vfs.FileSystem, top int) {
+	files, err := topFiles(fsys)
+	if err != nil {
+		log.Fatalf("err: %s", err)
+	}
+
+	stats := getStatsFromFilesParallel(fsys, files, jobsFlag)
 
 	sort.Sort(PcStatusList(stats))
 	// TODO 修正切片长度小于 top 的时候的报错
@@ -144,19 +290,190 @@ func top(top int) {
 	formatStats(topStats)
 }
 
+// serve runs hcache as a long-running exporter, rescanning the configured
+// targets (-pid/-cgroup/-container/file arguments, or a full -top scan if
+// none were given) every interval and blocking on an HTTP server that
+// exposes the result as Prometheus metrics and JSON.
+func serve(fsys vfs.FileSystem, addr string, interval time.Duration) {
+	scrape := func() ([]exporter.FileStat, error) {
+		files, err := resolveTargetFiles(fsys)
+		if err != nil {
+			return nil, err
+		}
+
+		// with no explicit -pid/-cgroup/-container/file targets, fall back
+		// to the same whole-host scan -top drives for a one-shot run
+		if len(files) == 0 {
+			files, err = topFiles(fsys)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		stats := getStatsFromFiles(fsys, files)
+		out := make([]exporter.FileStat, 0, len(stats))
+		for _, s := range stats {
+			out = append(out, exporter.FileStat{
+				Name:    s.Name,
+				Size:    s.Size,
+				Pages:   int64(s.Pages),
+				Cached:  int64(s.Cached),
+				Percent: s.Percent,
+			})
+		}
+		return out, nil
+	}
+
+	exp := exporter.New(scrape, interval)
+	exp.Start(make(chan struct{}))
+
+	if err := exp.ListenAndServe(addr); err != nil {
+		log.Fatalf("exporter: %v", err)
+	}
+}
+
+// toSnapshot converts the stats hcache already knows how to gather into the
+// schema pkg/snapshot persists.
+func toSnapshot(stats PcStatusList) snapshot.Snapshot {
+	snap := snapshot.Snapshot{Taken: time.Now(), Files: make([]snapshot.FileStat, 0, len(stats))}
+	for _, s := range stats {
+		snap.Files = append(snap.Files, snapshot.FileStat{
+			Name:    s.Name,
+			Size:    s.Size,
+			Pages:   s.Pages,
+			Cached:  s.Cached,
+			Percent: s.Percent,
+		})
+	}
+	return snap
+}
+
+// scanTargets resolves the configured -pid/-cgroup/-container/file targets,
+// falling back to a whole-host -top-style scan when none were given, so
+// -snapshot/-diff/-watch behave like every other mode when run with no
+// explicit targets.
+func scanTargets(fsys vfs.FileSystem) (PcStatusList, error) {
+	files, err := resolveTargetFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		files, err = topFiles(fsys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return getStatsFromFilesParallel(fsys, files, jobsFlag), nil
+}
+
+func writeSnapshot(fsys vfs.FileSystem, path string) {
+	stats, err := scanTargets(fsys)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	if err := snapshot.Save(path, toSnapshot(stats)); err != nil {
+		log.Fatalf("could not write snapshot %q: %v", path, err)
+	}
+}
+
+// runDiff loads oldPath as the "before" snapshot and either loads args[0]
+// as the "after" snapshot or, if no second snapshot was given, takes a
+// fresh live scan to diff against.
+func runDiff(fsys vfs.FileSystem, oldPath string, args []string) {
+	oldSnap, err := snapshot.Load(oldPath)
+	if err != nil {
+		log.Fatalf("could not load snapshot %q: %v", oldPath, err)
+	}
+
+	var newSnap snapshot.Snapshot
+	if len(args) > 0 {
+		newSnap, err = snapshot.Load(args[0])
+		if err != nil {
+			log.Fatalf("could not load snapshot %q: %v", args[0], err)
+		}
+	} else {
+		stats, err := scanTargets(fsys)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		newSnap = toSnapshot(stats)
+	}
+
+	snapshot.FormatText(snapshot.Diff(oldSnap, newSnap), topFlag)
+}
+
+// watch keeps a rolling in-memory snapshot and prints the top files by
+// |Δcached pages| every interval, useful for watching what's thrashing the
+// cache right after a deploy or backup job.
+func watch(fsys vfs.FileSystem, interval time.Duration, top int) {
+	if top <= 0 {
+		top = 20
+	}
+
+	scan := func() snapshot.Snapshot {
+		stats, err := scanTargets(fsys)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		return toSnapshot(stats)
+	}
+
+	prev := scan()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cur := scan()
+		snapshot.FormatText(snapshot.Diff(prev, cur), top)
+		prev = cur
+	}
+}
+
 func main() {
 	flag.Parse()
 
+	if pagemapFlag {
+		printPagemap(flag.Args())
+		os.Exit(0)
+	}
+
+	fsys, err := resolveFileSystem()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	if serveFlag != "" {
+		serve(fsys, serveFlag, intervalFlag)
+		os.Exit(0)
+	}
+
+	if snapshotFlag != "" {
+		writeSnapshot(fsys, snapshotFlag)
+		os.Exit(0)
+	}
+
+	if diffFlag != "" {
+		runDiff(fsys, diffFlag, flag.Args())
+		os.Exit(0)
+	}
+
+	if watchFlag != 0 {
+		watch(fsys, watchFlag, topFlag)
+		os.Exit(0)
+	}
+
 	if topFlag != 0 {
-		top(topFlag)
+		top(fsys, topFlag)
 		os.Exit(0)
 	}
 
-	files := flag.Args()
-	if pidFlag != 0 {
-		pcstat.SwitchMountNs(pidFlag)
-		maps := getPidLds(pidFlag)
-		files = append(files, maps...)
+	files, err := resolveTargetFiles(fsys)
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
 
 	// all non-flag arguments are considered to be filenames
@@ -167,21 +484,116 @@ func main() {
 		os.Exit(1)
 	}
 
-	stats := getStatsFromFiles(files)
+	stats := getStatsFromFiles(fsys, files)
 	sort.Sort(PcStatusList(stats))
 	formatStats(stats)
 }
 
-func getPidLds(pid int) []string {
+// printPagemap implements the -pagemap mode: it's the protocol -ssh-helper
+// execs on the remote (defaulting to the hcache binary itself), so a stock
+// hcache install can serve as its own Mincore helper over SSH without a
+// separate out-of-tree binary. It always reads mincore(2) on the live host
+// it runs on, ignoring -archive/-ssh, and prints one '0'/'1' character per
+// page on stdout, matching what vfs.SSH.Mincore parses back.
+func printPagemap(args []string) {
+	if len(args) != 1 {
+		log.Fatalf("-pagemap takes exactly one file argument")
+	}
+
+	pages, err := vfs.Local{}.Mincore(args[0])
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	var sb strings.Builder
+	for _, resident := range pages {
+		if resident {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	fmt.Println(sb.String())
+}
+
+// resolveFileSystem picks the vfs.FileSystem backend to analyze based on
+// -archive/-ssh, defaulting to the live host.
+func resolveFileSystem() (vfs.FileSystem, error) {
+	switch {
+	case archiveFlag != "" && sshFlag != "":
+		return nil, fmt.Errorf("-archive and -ssh are mutually exclusive")
+	case archiveFlag != "":
+		return vfs.OpenArchive(archiveFlag)
+	case sshFlag != "":
+		user, addr := splitSSHTarget(sshFlag)
+		return vfs.DialSSHWithAgent(addr, user, sshHelperFlag)
+	default:
+		return vfs.Local{}, nil
+	}
+}
+
+// splitSSHTarget parses a "-ssh" value of the form "user@host[:port]",
+// defaulting the user to $USER and the port to 22 when omitted.
+func splitSSHTarget(target string) (user, addr string) {
+	user = os.Getenv("USER")
+	if at := strings.Index(target, "@"); at >= 0 {
+		user = target[:at]
+		target = target[at+1:]
+	}
+
+	addr = target
+	if !strings.Contains(addr, ":") {
+		addr += ":22"
+	}
+	return user, addr
+}
+
+// resolveTargetFiles gathers the files to report on from every targeting
+// flag (-pid, -cgroup, -container) plus any bare file arguments, the same
+// way main() has always combined them, so callers like the exporter's
+// scrape loop can reuse the exact same targeting logic on every tick.
+func resolveTargetFiles(fsys vfs.FileSystem) ([]string, error) {
+	files := flag.Args()
+	if pidFlag != 0 {
+		switchMountNs(fsys, pidFlag)
+		maps := getPidLds(fsys, pidFlag)
+		files = append(files, maps...)
+	}
+
+	if cgroupFlag != "" || containerFlag != "" {
+		cgroupPath := cgroupFlag
+		if cgroupPath == "" {
+			resolved, err := resolveContainerCgroup(fsys, containerFlag)
+			if err != nil {
+				return nil, fmt.Errorf("could not resolve container %q to a cgroup: %w", containerFlag, err)
+			}
+			cgroupPath = resolved
+		}
+
+		pids, err := getCgroupPids(fsys, cgroupPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read cgroup %q: %w", cgroupPath, err)
+		}
+
+		maps := getPidsLds(fsys, pids)
+		files = append(files, maps...)
+	}
+
+	uniqueSlice(&files)
+
+	return files, nil
+}
+
+func getPidLds(fsys vfs.FileSystem, pid int) []string {
 	// ignore the process of hcache itself
 	if pid == os.Getpid() {
 		return []string{}
 	}
 
 	dirname := fmt.Sprintf("/proc/%d/fd", pid)
-	entries, err := os.ReadDir(dirname)
+	entries, err := fsys.ReadDir(dirname)
 	if err != nil {
-		if !strings.Contains(err.Error(), "no such file or directory") {
+		if !errors.Is(err, fs.ErrNotExist) {
 			log.Fatalf("could not open dir '%s': %v", dirname, err)
 		}
 		log.Printf("skipping %s: %v", dirname, err)
@@ -192,27 +604,21 @@ func getPidLds(pid int) []string {
 	maps := make(map[string]bool)
 
 	for _, entry := range entries {
-		if !entry.IsDir() {
-			symlink := fmt.Sprintf("/proc/%d/fd/%s", pid, entry.Name())
-			fi, err := os.Lstat(symlink)
-			if err != nil {
-				log.Printf("could not open '%s' for read: %v", symlink, err)
-				continue
-			}
-			// judge whether the file is a symlink, here, the result is true if the file is a symlink
-			if fi.Mode()&os.ModeSymlink != 0 {
-				target, err := filepath.EvalSymlinks(symlink)
-				if err != nil {
-					// ignore file not found error because this is quite common
-					if !strings.Contains(err.Error(), "no such file or directory") {
-						log.Printf("could not inspect symlink '%s': %v", symlink, err)
-					}
-					continue
-				}
-				if strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "/dev") && !strings.HasPrefix(target, "/proc") {
-					maps[target] = true
-				}
+		if entry.IsDir {
+			continue
+		}
+
+		symlink := fmt.Sprintf("/proc/%d/fd/%s", pid, entry.Name)
+		target, err := fsys.Readlink(symlink)
+		if err != nil {
+			// ignore file not found error because this is quite common
+			if !errors.Is(err, fs.ErrNotExist) {
+				log.Printf("could not inspect symlink '%s': %v", symlink, err)
 			}
+			continue
+		}
+		if strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "/dev") && !strings.HasPrefix(target, "/proc") {
+			maps[target] = true
 		}
 	}
 
@@ -224,3 +630,82 @@ func getPidLds(pid int) []string {
 
 	return out
 }
+
+// getPidsLds switches into each pid's mount namespace (so overlayfs-backed
+// paths inside containers resolve to their real backing files on the host,
+// same as switchMountNs does for -pid and -top) and unions the open file
+// maps for every pid, the same way top() does for a whole-host scan.
+func getPidsLds(fsys vfs.FileSystem, pids []int) []string {
+	var files []string
+
+	for _, pid := range pids {
+		switchMountNs(fsys, pid)
+		maps := getPidLds(fsys, pid)
+		files = append(files, maps...)
+	}
+
+	uniqueSlice(&files)
+
+	return files
+}
+
+// getCgroupPids reads the pids of every process that belongs to a cgroup,
+// supporting both the unified cgroup v2 layout (a single cgroup.procs file)
+// and the legacy v1 layout (cgroup.procs under one or more controller
+// hierarchies, e.g. .../memory/.../cgroup.procs).
+func getCgroupPids(fsys vfs.FileSystem, cgroupPath string) ([]int, error) {
+	procsPath := cgroupPath
+	if filepath.Base(procsPath) != "cgroup.procs" {
+		procsPath = filepath.Join(cgroupPath, "cgroup.procs")
+	}
+
+	f, err := fsys.Open(procsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var pids []int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		pid, err := strconv.Atoi(line)
+		if err != nil {
+			log.Printf("skipping malformed pid %q in %s: %v", line, procsPath, err)
+			continue
+		}
+		pids = append(pids, pid)
+	}
+
+	return pids, scanner.Err()
+}
+
+// resolveContainerCgroup maps a Docker/Podman/containerd container ID to the
+// cgroup directory holding its cgroup.procs file by probing the handful of layouts those runtimes are
+// known to use, since there's no single canonical path across runtimes and
+// cgroup versions.
+func resolveContainerCgroup(fsys vfs.FileSystem, containerID string) (string, error) {
+	candidates := []string{
+		// cgroup v2, unified hierarchy, systemd-managed docker/podman/containerd
+		fmt.Sprintf("/sys/fs/cgroup/system.slice/docker-%s.scope", containerID),
+		fmt.Sprintf("/sys/fs/cgroup/system.slice/libpod-%s.scope", containerID),
+		fmt.Sprintf("/sys/fs/cgroup/machine.slice/libpod-%s.scope", containerID),
+		// cgroup v2, cgroupfs driver
+		fmt.Sprintf("/sys/fs/cgroup/docker/%s", containerID),
+		// cgroup v1, per-controller (memory is as good as any single controller
+		// for enumerating member pids, since cgroup.procs is controller-agnostic)
+		fmt.Sprintf("/sys/fs/cgroup/memory/docker/%s", containerID),
+		fmt.Sprintf("/sys/fs/cgroup/memory/system.slice/docker-%s.scope", containerID),
+	}
+
+	for _, candidate := range candidates {
+		if _, err := fsys.Stat(filepath.Join(candidate, "cgroup.procs")); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("no known cgroup layout contains a container with ID %q", containerID)
+}
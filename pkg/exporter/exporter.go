@@ -0,0 +1,143 @@
+// Package exporter turns a one-shot hcache scan into a long-running
+// Prometheus exporter: it periodically re-runs a scrape function and serves
+// the most recent result over HTTP, both as Prometheus text format and as
+// JSON mirroring the CLI's own -json output.
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FileStat is the per-file cache snapshot the exporter serves. It is
+// intentionally smaller than pcstat.PcStatus (no per-page bitmap) so this
+// package stays decoupled from hcache's CLI-local types.
+type FileStat struct {
+	Name    string  `json:"filename"`
+	Size    int64   `json:"size"`
+	Pages   int64   `json:"pages"`
+	Cached  int64   `json:"cached"`
+	Percent float64 `json:"percent"`
+}
+
+// ScrapeFunc resolves the current set of files to report on and returns
+// their cache status. main.go supplies a closure that reuses the existing
+// -pid/-cgroup/-container/file-argument resolution logic.
+type ScrapeFunc func() ([]FileStat, error)
+
+// Exporter periodically calls Scrape and caches the result so concurrent
+// HTTP scrapes see a consistent snapshot instead of racing a fresh /proc
+// walk on every request.
+type Exporter struct {
+	scrape   ScrapeFunc
+	interval time.Duration
+
+	mu      sync.RWMutex
+	stats   []FileStat
+	scraped time.Time
+	err     error
+}
+
+// New creates an Exporter that refreshes its cached stats every interval.
+// Call Start to begin the refresh loop before serving requests.
+func New(scrape ScrapeFunc, interval time.Duration) *Exporter {
+	return &Exporter{scrape: scrape, interval: interval}
+}
+
+// Start runs the refresh loop in the background until stop is closed.
+func (e *Exporter) Start(stop <-chan struct{}) {
+	e.refresh()
+
+	ticker := time.NewTicker(e.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				e.refresh()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+func (e *Exporter) refresh() {
+	stats, err := e.scrape()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.scraped = time.Now()
+	if err != nil {
+		e.err = err
+		log.Printf("exporter: scrape failed: %v", err)
+		return
+	}
+	e.stats = stats
+	e.err = nil
+}
+
+func (e *Exporter) snapshot() ([]FileStat, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.stats, e.err
+}
+
+// ServeMetrics writes the cached stats in Prometheus text exposition format.
+func (e *Exporter) ServeMetrics(w http.ResponseWriter, r *http.Request) {
+	stats, err := e.snapshot()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP hcache_pages_cached_total Pages of the file currently resident in the page cache.")
+	fmt.Fprintln(w, "# TYPE hcache_pages_cached_total gauge")
+	for _, s := range stats {
+		fmt.Fprintf(w, "hcache_pages_cached_total{filename=%q} %d\n", s.Name, s.Cached)
+	}
+
+	fmt.Fprintln(w, "# HELP hcache_pages_total Total pages that make up the file.")
+	fmt.Fprintln(w, "# TYPE hcache_pages_total gauge")
+	for _, s := range stats {
+		fmt.Fprintf(w, "hcache_pages_total{filename=%q} %d\n", s.Name, s.Pages)
+	}
+
+	fmt.Fprintln(w, "# HELP hcache_cached_percent Percentage of the file currently resident in the page cache.")
+	fmt.Fprintln(w, "# TYPE hcache_cached_percent gauge")
+	for _, s := range stats {
+		fmt.Fprintf(w, "hcache_cached_percent{filename=%q} %f\n", s.Name, s.Percent)
+	}
+}
+
+// ServeJSON writes the cached stats as JSON, mirroring the shape of the
+// CLI's -json output (see FormatJson).
+func (e *Exporter) ServeJSON(w http.ResponseWriter, r *http.Request) {
+	stats, err := e.snapshot()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		log.Printf("exporter: failed to encode json response: %v", err)
+	}
+}
+
+// ListenAndServe registers the /metrics and /stats.json handlers and blocks
+// serving HTTP on addr (e.g. ":9099").
+func (e *Exporter) ListenAndServe(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", e.ServeMetrics)
+	mux.HandleFunc("/stats.json", e.ServeJSON)
+
+	log.Printf("exporter: serving hcache metrics on %s", addr)
+	return http.ListenAndServe(addr, mux)
+}
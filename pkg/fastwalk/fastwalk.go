@@ -0,0 +1,58 @@
+// Package fastwalk parallelizes scans over many independent paths across a
+// worker pool, mirroring the strategy x/tools' internal/fastwalk package
+// uses to keep large directory trees from bottlenecking on a single
+// goroutine doing the traversal serially. hcache uses it to fan PID and fd
+// scanning of /proc out across goroutines instead of visiting one pid at a
+// time.
+package fastwalk
+
+import "sync"
+
+// VisitFunc is called once per path by a worker goroutine. Errors are
+// collected rather than aborting the walk so one bad pid/fd doesn't stop
+// the rest of the scan.
+type VisitFunc func(path string) error
+
+// Walk fans paths out across workers goroutines, each repeatedly pulling
+// the next path and running fn on it, and blocks until every path has been
+// visited. A workers value less than 1 is treated as 1.
+func Walk(paths []string, workers int, fn VisitFunc) []error {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan string)
+	errs := make(chan error, len(paths))
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				if err := fn(p); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	for _, p := range paths {
+		jobs <- p
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	var out []error
+	for err := range errs {
+		out = append(out, err)
+	}
+	return out
+}
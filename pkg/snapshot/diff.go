@@ -0,0 +1,91 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// Delta is the change in a single file's cache status between two
+// snapshots. A file present in only one snapshot diffs against a zero
+// FileStat, so it shows up as fully evicted or fully newly-cached.
+type Delta struct {
+	Name         string
+	OldPercent   float64
+	NewPercent   float64
+	PagesDelta   int
+	PercentDelta float64
+}
+
+// Diff compares two snapshots and returns one Delta per file that appears
+// in either, sorted by |PagesDelta| descending so the most-churned files
+// sort first - the same "interesting first" ordering -top's PcStatusList
+// sort gives the CLI's other views.
+func Diff(old, new Snapshot) []Delta {
+	oldByName := make(map[string]FileStat, len(old.Files))
+	for _, f := range old.Files {
+		oldByName[f.Name] = f
+	}
+	newByName := make(map[string]FileStat, len(new.Files))
+	for _, f := range new.Files {
+		newByName[f.Name] = f
+	}
+
+	seen := make(map[string]bool, len(oldByName)+len(newByName))
+	var deltas []Delta
+
+	visit := func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+
+		o := oldByName[name]
+		n := newByName[name]
+
+		deltas = append(deltas, Delta{
+			Name:         name,
+			OldPercent:   o.Percent,
+			NewPercent:   n.Percent,
+			PagesDelta:   n.Cached - o.Cached,
+			PercentDelta: n.Percent - o.Percent,
+		})
+	}
+
+	for _, f := range old.Files {
+		visit(f.Name)
+	}
+	for _, f := range new.Files {
+		visit(f.Name)
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		return absInt(deltas[i].PagesDelta) > absInt(deltas[j].PagesDelta)
+	})
+
+	return deltas
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// FormatText writes deltas as an aligned table to stdout, capped at the
+// first top entries (0 means no cap), the same truncate-after-sort
+// behavior -top uses.
+func FormatText(deltas []Delta, top int) {
+	if top > 0 && top < len(deltas) {
+		deltas = deltas[:top]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Δ PAGES\tOLD %\tNEW %\tNAME")
+	for _, d := range deltas {
+		fmt.Fprintf(w, "%+d\t%.2f\t%.2f\t%s\n", d.PagesDelta, d.OldPercent, d.NewPercent, d.Name)
+	}
+	w.Flush()
+}
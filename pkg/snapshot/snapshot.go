@@ -0,0 +1,58 @@
+// Package snapshot serializes a point-in-time capture of hcache's page
+// cache stats to disk, so -snapshot/-diff/-watch can track cache churn
+// across separate runs instead of only ever reporting the current instant.
+package snapshot
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FileStat is one file's cache status at the moment a Snapshot was taken.
+// It mirrors the handful of fields hcache's own PcStatus carries that are
+// meaningful to diff over time.
+type FileStat struct {
+	Name    string
+	Size    int64
+	Pages   int
+	Cached  int
+	Percent float64
+}
+
+// Snapshot is the on-disk schema -snapshot writes and -diff/-watch read: a
+// timestamp plus every file's cache status at that instant.
+type Snapshot struct {
+	Taken time.Time
+	Files []FileStat
+}
+
+// Save gob-encodes snap to path, overwriting it if it already exists.
+func Save(path string, snap Snapshot) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := gob.NewEncoder(f).Encode(snap); err != nil {
+		return fmt.Errorf("encoding snapshot to %q: %w", path, err)
+	}
+	return nil
+}
+
+// Load decodes a Snapshot previously written by Save.
+func Load(path string) (Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer f.Close()
+
+	var snap Snapshot
+	if err := gob.NewDecoder(f).Decode(&snap); err != nil {
+		return Snapshot{}, fmt.Errorf("decoding snapshot %q: %w", path, err)
+	}
+	return snap, nil
+}
@@ -0,0 +1,181 @@
+package vfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// ErrNoMincore is returned by Archive.Mincore when the snapshot has no
+// recorded page-cache bitmap for the requested path: mincore(2) can't be
+// replayed after the fact, so the collector that captured the archive has
+// to have saved one alongside each file it recorded.
+var ErrNoMincore = errors.New("vfs: archive has no recorded mincore data for this path")
+
+// Archive implements FileSystem by reading files out of a tar (optionally
+// gzip-compressed) or zip archive of a /proc snapshot collected by a
+// lightweight collector, instead of the live filesystem. A sibling
+// "<path>.mincore" entry, one byte per page (non-zero meaning cached),
+// supplies the data Mincore would otherwise get from the live mincore(2)
+// syscall.
+type Archive struct {
+	files map[string][]byte
+}
+
+// OpenArchive loads every regular-file entry of the tar or zip archive at
+// archivePath into memory, keyed by its original absolute path.
+func OpenArchive(archivePath string) (*Archive, error) {
+	if strings.HasSuffix(archivePath, ".zip") {
+		return openZipArchive(archivePath)
+	}
+	return openTarArchive(archivePath)
+}
+
+func openTarArchive(archivePath string) (*Archive, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(archivePath, ".gz") || strings.HasSuffix(archivePath, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	a := &Archive{files: make(map[string][]byte)}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		buf, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		a.files[normalize(hdr.Name)] = buf
+	}
+
+	return a, nil
+}
+
+func openZipArchive(archivePath string) (*Archive, error) {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	a := &Archive{files: make(map[string][]byte)}
+
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		buf, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		a.files[normalize(zf.Name)] = buf
+	}
+
+	return a, nil
+}
+
+func normalize(name string) string {
+	return "/" + strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func (a *Archive) Open(p string) (io.ReadCloser, error) {
+	buf, ok := a.files[normalize(p)]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(buf)), nil
+}
+
+func (a *Archive) Stat(p string) (FileInfo, error) {
+	buf, ok := a.files[normalize(p)]
+	if !ok {
+		return FileInfo{}, os.ErrNotExist
+	}
+	return FileInfo{Name: path.Base(p), Size: int64(len(buf))}, nil
+}
+
+func (a *Archive) ReadDir(p string) ([]DirEntry, error) {
+	prefix := normalize(p)
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	seen := make(map[string]bool)
+	var out []DirEntry
+	for name := range a.files {
+		if !strings.HasPrefix(name, prefix) || name == prefix {
+			continue
+		}
+
+		rel := strings.SplitN(strings.TrimPrefix(name, prefix), "/", 2)
+		child := rel[0]
+		if child == "" || seen[child] {
+			continue
+		}
+		seen[child] = true
+		out = append(out, DirEntry{Name: child, IsDir: len(rel) > 1})
+	}
+
+	if len(out) == 0 {
+		return nil, os.ErrNotExist
+	}
+	return out, nil
+}
+
+// Readlink returns the recorded target of a "<path>.symlink" sidecar entry,
+// since a plain archive member has no symlink metadata of its own.
+func (a *Archive) Readlink(p string) (string, error) {
+	buf, ok := a.files[normalize(p)+".symlink"]
+	if !ok {
+		return "", os.ErrNotExist
+	}
+	return strings.TrimSpace(string(buf)), nil
+}
+
+func (a *Archive) Mincore(p string) ([]bool, error) {
+	buf, ok := a.files[normalize(p)+".mincore"]
+	if !ok {
+		return nil, ErrNoMincore
+	}
+
+	pages := make([]bool, len(buf))
+	for i, b := range buf {
+		pages[i] = b != 0
+	}
+	return pages, nil
+}
@@ -0,0 +1,62 @@
+package vfs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	pcstat "github.com/tobert/pcstat/pkg"
+)
+
+// Local is the FileSystem backend for the live host hcache runs on: plain
+// os.* calls plus the mincore(2) syscall via pcstat.GetPcStatus. This is
+// the behavior hcache has always had before -archive/-ssh existed.
+type Local struct{}
+
+func (Local) Open(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+func (Local) Stat(path string) (FileInfo, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	return FileInfo{
+		Name:    fi.Name(),
+		Size:    fi.Size(),
+		Mode:    fi.Mode(),
+		ModTime: fi.ModTime(),
+		IsDir:   fi.IsDir(),
+	}, nil
+}
+
+func (Local) ReadDir(path string) ([]DirEntry, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]DirEntry, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, DirEntry{Name: e.Name(), IsDir: e.IsDir()})
+	}
+	return out, nil
+}
+
+func (Local) Readlink(path string) (string, error) {
+	return filepath.EvalSymlinks(path)
+}
+
+func (Local) Mincore(path string) ([]bool, error) {
+	status, err := pcstat.GetPcStatus(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]bool, len(status.PPStat))
+	for i, p := range status.PPStat {
+		pages[i] = p
+	}
+	return pages, nil
+}
@@ -0,0 +1,184 @@
+package vfs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// SSH implements FileSystem over a single SSH connection by running plain
+// commands (cat, stat, ls, readlink) on the remote host, so reading files
+// and /proc can be driven from a jump host without installing hcache on the
+// target. mincore(2) can't be invoked remotely through a shell, so Mincore
+// does need a helper on the remote (normally hcache itself): it execs it
+// and parses its pagemap output.
+type SSH struct {
+	client *ssh.Client
+	helper string
+}
+
+// DialSSH connects to addr ("host:port") as user using auth, and arranges
+// for Mincore to exec helperPath on the remote to read back page-cache
+// bitmaps. helperPath may be empty, in which case Mincore always returns
+// ErrNoMincore.
+func DialSSH(addr, user string, auth []ssh.AuthMethod, helperPath string) (*SSH, error) {
+	config := &ssh.ClientConfig{
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+
+	client, err := ssh.Dial("tcp", addr, config)
+	if err != nil {
+		return nil, fmt.Errorf("vfs: ssh dial %s: %w", addr, err)
+	}
+
+	return &SSH{client: client, helper: helperPath}, nil
+}
+
+// DialSSHWithAgent is a convenience wrapper around DialSSH that authenticates
+// through the running ssh-agent (via $SSH_AUTH_SOCK), the common case for an
+// operator jumping from their workstation to analyze a remote host.
+func DialSSHWithAgent(addr, user, helperPath string) (*SSH, error) {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, fmt.Errorf("vfs: SSH_AUTH_SOCK is not set, cannot authenticate to %s", addr)
+	}
+
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("vfs: dialing ssh-agent: %w", err)
+	}
+
+	ag := agent.NewClient(conn)
+	return DialSSH(addr, user, []ssh.AuthMethod{ssh.PublicKeysCallback(ag.Signers)}, helperPath)
+}
+
+func (s *SSH) run(cmd string) (string, error) {
+	session, err := s.client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	if err := session.Run(cmd); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		// normalize every "missing file" shape the remote shell can produce
+		// (ls/stat/readlink all phrase it slightly differently, and
+		// capitalization varies by locale) to fs.ErrNotExist, the same
+		// sentinel Local and Archive use, so callers can check with a
+		// single errors.Is instead of matching backend-specific text.
+		if strings.Contains(strings.ToLower(msg), "no such file or directory") {
+			return "", fmt.Errorf("vfs: remote command %q failed: %w", cmd, fs.ErrNotExist)
+		}
+		return "", fmt.Errorf("vfs: remote command %q failed: %w: %s", cmd, err, msg)
+	}
+	return stdout.String(), nil
+}
+
+type sshReadCloser struct {
+	io.Reader
+	session *ssh.Session
+}
+
+func (r *sshReadCloser) Close() error {
+	r.session.Wait()
+	return r.session.Close()
+}
+
+func (s *SSH) Open(path string) (io.ReadCloser, error) {
+	session, err := s.client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	if err := session.Start(fmt.Sprintf("cat -- %s", shellQuote(path))); err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	return &sshReadCloser{Reader: out, session: session}, nil
+}
+
+func (s *SSH) Stat(path string) (FileInfo, error) {
+	out, err := s.run(fmt.Sprintf("stat -c '%%s' -- %s", shellQuote(path)))
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	size, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("vfs: parsing remote stat output for %q: %w", path, err)
+	}
+
+	return FileInfo{Name: path, Size: size}, nil
+}
+
+func (s *SSH) ReadDir(dir string) ([]DirEntry, error) {
+	out, err := s.run(fmt.Sprintf("ls -1A -- %s", shellQuote(dir)))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []DirEntry
+	for _, name := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if name == "" {
+			continue
+		}
+		entries = append(entries, DirEntry{Name: name})
+	}
+	return entries, nil
+}
+
+func (s *SSH) Readlink(path string) (string, error) {
+	out, err := s.run(fmt.Sprintf("readlink -f -- %s", shellQuote(path)))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Mincore execs the remote helper binary (e.g. hcache itself) and expects
+// it to print a string of '0'/'1' characters, one per page, on stdout.
+func (s *SSH) Mincore(path string) ([]bool, error) {
+	if s.helper == "" {
+		return nil, ErrNoMincore
+	}
+
+	out, err := s.run(fmt.Sprintf("%s -pagemap -- %s", s.helper, shellQuote(path)))
+	if err != nil {
+		return nil, err
+	}
+
+	out = strings.TrimSpace(out)
+	pages := make([]bool, 0, len(out))
+	for _, c := range out {
+		pages = append(pages, c == '1')
+	}
+	return pages, nil
+}
+
+// shellQuote wraps path in single quotes for the remote shell, escaping any
+// single quotes it contains, since paths coming from another process's fds
+// are not otherwise guaranteed to be free of shell metacharacters.
+func shellQuote(path string) string {
+	return "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+}
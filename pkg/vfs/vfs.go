@@ -0,0 +1,52 @@
+// Package vfs abstracts the filesystem and mincore(2) operations hcache
+// needs to compute page cache stats, analogous to the FileSystem interface
+// pattern in golang.org/x/net/webdav and golang.org/x/tools/godoc/vfs.
+// hcache's reads of the analyzed files, of /proc/<pid>/fd, of cgroup.procs
+// and of mincore go through an implementation of FileSystem, so the same
+// analysis code can run against the live host, an archived /proc snapshot,
+// or a remote host over SSH. -top's list of processes is not among them: it
+// always comes from the live host.
+package vfs
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// FileInfo is the subset of file metadata hcache needs. It's a plain value
+// rather than os.FileInfo so backends that can't produce a real
+// os.FileInfo (an archived snapshot, a remote stat(1) call) don't have to
+// fake one.
+type FileInfo struct {
+	Name    string
+	Size    int64
+	Mode    os.FileMode
+	ModTime time.Time
+	IsDir   bool
+}
+
+// DirEntry is a single entry returned by FileSystem.ReadDir.
+type DirEntry struct {
+	Name  string
+	IsDir bool
+}
+
+// FileSystem is everything hcache needs to know about a host to compute
+// page cache stats for its files.
+type FileSystem interface {
+	// Open opens the file at path for reading.
+	Open(path string) (io.ReadCloser, error)
+	// Stat returns metadata for path.
+	Stat(path string) (FileInfo, error)
+	// ReadDir lists the entries of the directory at path.
+	ReadDir(path string) ([]DirEntry, error)
+	// Readlink resolves path to its final target, following every
+	// intermediate symlink (the same as filepath.EvalSymlinks), so
+	// overlayfs-mounted paths inside a container resolve to their real
+	// backing file.
+	Readlink(path string) (string, error)
+	// Mincore reports, for each page of the file at path, whether it is
+	// currently resident in the page cache.
+	Mincore(path string) ([]bool, error)
+}